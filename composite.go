@@ -0,0 +1,125 @@
+package datadog
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// CompositePropagator injects and extracts both the W3C Trace Context
+// headers (traceparent/tracestate) and the Datadog x-datadog-* headers,
+// keeping the two trace IDs correlated the way dd-trace-go does when
+// interoperating with OpenTelemetry.
+//
+// The zero value, CompositePropagator{}, is ready to use and behaves the
+// same as NewDatadogW3C(): propagation.TraceContext{} paired with
+// Propagator{}.
+type CompositePropagator struct {
+	w3c propagation.TextMapPropagator
+	dd  propagation.TextMapPropagator
+}
+
+// Asserts that the propagator implements the otel.TextMapPropagator interface at compile time.
+var _ propagation.TextMapPropagator = &CompositePropagator{}
+
+// NewDatadogW3C returns a TextMapPropagator that emits both Datadog and W3C
+// Trace Context headers on Inject, and on Extract reconciles the two:
+//
+//   - if both are present and their lower 64 bits agree, the W3C 128-bit
+//     trace ID is used;
+//   - if they disagree, the W3C trace ID is still preferred, but the
+//     conflicting Datadog IDs are attached to a "dd=" TraceState entry for
+//     debugging;
+//   - if only Datadog headers are present, a W3C-compatible 128-bit trace ID
+//     is synthesized by left-padding the 64-bit Datadog trace ID with zeros.
+func NewDatadogW3C() propagation.TextMapPropagator {
+	return &CompositePropagator{
+		w3c: propagation.TraceContext{},
+		dd:  Propagator{},
+	}
+}
+
+// Inject injects a context to the carrier following both the W3C Trace
+// Context and Datadog formats.
+func (c *CompositePropagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	c.w3cPropagator().Inject(ctx, carrier)
+	c.ddPropagator().Inject(ctx, carrier)
+}
+
+// Extract gets a context from the carrier, reconciling W3C and Datadog
+// headers when both are present. See NewDatadogW3C for the reconciliation
+// rules.
+func (c *CompositePropagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	w3cSC := trace.SpanContextFromContext(c.w3cPropagator().Extract(context.Background(), carrier))
+	ddSC := trace.SpanContextFromContext(c.ddPropagator().Extract(context.Background(), carrier))
+
+	switch {
+	case w3cSC.IsValid() && ddSC.IsValid():
+		sc := w3cSC
+		if lowerTraceIDHex(w3cSC.TraceID()) != lowerTraceIDHex(ddSC.TraceID()) {
+			if ts, ok := attachConflictingDatadogIDs(w3cSC.TraceState(), ddSC); ok {
+				sc = sc.WithTraceState(ts)
+			}
+		}
+		return trace.ContextWithRemoteSpanContext(ctx, sc)
+	case w3cSC.IsValid():
+		return trace.ContextWithRemoteSpanContext(ctx, w3cSC)
+	case ddSC.IsValid():
+		return trace.ContextWithRemoteSpanContext(ctx, ddSC)
+	default:
+		return ctx
+	}
+}
+
+// Fields returns the union of the fields set by the W3C and Datadog
+// propagators.
+func (c *CompositePropagator) Fields() []string {
+	seen := make(map[string]bool)
+	var fields []string
+	for _, f := range append(c.w3cPropagator().Fields(), c.ddPropagator().Fields()...) {
+		if seen[f] {
+			continue
+		}
+		seen[f] = true
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+// w3cPropagator returns the configured W3C propagator, defaulting to
+// propagation.TraceContext{} for the zero value.
+func (c *CompositePropagator) w3cPropagator() propagation.TextMapPropagator {
+	if c.w3c != nil {
+		return c.w3c
+	}
+	return propagation.TraceContext{}
+}
+
+// ddPropagator returns the configured Datadog propagator, defaulting to
+// Propagator{} for the zero value.
+func (c *CompositePropagator) ddPropagator() propagation.TextMapPropagator {
+	if c.dd != nil {
+		return c.dd
+	}
+	return Propagator{}
+}
+
+// lowerTraceIDHex returns the last 16 hex characters (lower 64 bits) of id.
+func lowerTraceIDHex(id trace.TraceID) string {
+	s := id.String()
+	return s[len(s)-upperTraceIDLen:]
+}
+
+// attachConflictingDatadogIDs records the Datadog trace/span IDs that
+// disagreed with the preferred W3C trace ID, so they can still be found for
+// debugging.
+func attachConflictingDatadogIDs(ts trace.TraceState, ddSC trace.SpanContext) (trace.TraceState, bool) {
+	value := fmt.Sprintf("t.dd:%s;p.dd:%s", ddSC.TraceID().String(), ddSC.SpanID().String())
+	newTS, err := ts.Insert(ddTraceStateKey, value)
+	if err != nil {
+		return ts, false
+	}
+	return newTS, true
+}
@@ -0,0 +1,89 @@
+package datadog
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func traceparent(traceID trace.TraceID, spanID trace.SpanID, sampled bool) string {
+	flags := "00"
+	if sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", traceID.String(), spanID.String(), flags)
+}
+
+func TestCompositeExtractAgreeingTraceIDsPrefersW3C(t *testing.T) {
+	dd := NewDatadogW3C()
+	carrier := propagation.MapCarrier{
+		"traceparent":     traceparent(traceID128, spanID, true),
+		traceIDHeaderKey:  ddTraceID128,
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+		tagsHeaderKey:     ddTagsHeader,
+	}
+
+	sc := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+	assert.Equal(t, traceID128, sc.TraceID())
+	assert.Equal(t, spanID, sc.SpanID())
+	assert.Empty(t, sc.TraceState().Get(ddTraceStateKey))
+}
+
+func TestCompositeExtractDisagreeingTraceIDsPrefersW3CAndRecordsDatadog(t *testing.T) {
+	dd := NewDatadogW3C()
+	carrier := propagation.MapCarrier{
+		"traceparent":     traceparent(traceID, spanID, true),
+		traceIDHeaderKey:  ddTraceID128,
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+		tagsHeaderKey:     ddTagsHeader,
+	}
+
+	sc := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+	assert.Equal(t, traceID, sc.TraceID())
+	assert.Equal(t, fmt.Sprintf("t.dd:%s;p.dd:%s", traceID128.String(), spanID.String()), sc.TraceState().Get(ddTraceStateKey))
+}
+
+func TestCompositeExtractDatadogOnlySynthesizes128BitTraceID(t *testing.T) {
+	dd := NewDatadogW3C()
+	carrier := propagation.MapCarrier{
+		traceIDHeaderKey:  ddTraceID,
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+	}
+
+	sc := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+	assert.Equal(t, traceID, sc.TraceID())
+	assert.Equal(t, spanID, sc.SpanID())
+}
+
+func TestCompositeInjectEmitsBothFormats(t *testing.T) {
+	dd := NewDatadogW3C()
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID128,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	carrier := propagation.MapCarrier{}
+	dd.Inject(ctx, carrier)
+
+	assert.Equal(t, traceparent(traceID128, spanID, true), carrier.Get("traceparent"))
+	assert.Equal(t, ddTraceID128, carrier.Get(traceIDHeaderKey))
+	assert.Equal(t, ddTagsHeader, carrier.Get(tagsHeaderKey))
+}
+
+func TestCompositeFieldsIsUnion(t *testing.T) {
+	dd := NewDatadogW3C()
+	fields := dd.Fields()
+
+	assert.Contains(t, fields, "traceparent")
+	assert.Contains(t, fields, traceIDHeaderKey)
+	assert.Contains(t, fields, tagsHeaderKey)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
@@ -14,12 +15,27 @@ const (
 	traceIDHeaderKey  = "x-datadog-trace-id"
 	parentIDHeaderKey = "x-datadog-parent-id"
 	priorityHeaderKey = "x-datadog-sampling-priority"
+	tagsHeaderKey     = "x-datadog-tags"
 	// TODO: other headers: https://github.com/DataDog/dd-trace-go/blob/4f0b6ac22e14082ee1443d502a35a99cd9459ee0/ddtrace/tracer/textmap.go#L73-L96
 
 	// These are typical sampling values for Datadog, but Datadog libraries actually support any integer values
 	// values >=1 mean the trace is sampled, values <= 0 mean the trace is not sampled
 	notSampled = "0"
 	isSampled  = "1"
+
+	// upperTraceIDTagKey carries the upper 64 bits of a 128-bit trace ID as
+	// 16 lower-hex characters, per the Datadog 128-bit trace ID propagation
+	// format:
+	// https://github.com/DataDog/dd-trace-go/blob/4f0b6ac22e14082ee1443d502a35a99cd9459ee0/ddtrace/tracer/textmap.go
+	upperTraceIDTagKey = "_dd.p.tid"
+	upperTraceIDLen    = 16
+
+	traceStateHeaderKey = "tracestate"
+	originHeaderKey     = "x-datadog-origin"
+
+	// ddTraceStateKey is the vendor key this propagator mirrors Datadog
+	// fields into under the W3C TraceState, e.g. "dd=s:1;o:rum".
+	ddTraceStateKey = "dd"
 )
 
 var (
@@ -30,14 +46,85 @@ var (
 	errInvalidTraceIDHeader          = errors.New("invalid Datadog trace ID header found")
 	errInvalidSpanIDHeader           = errors.New("invalid Datadog span ID header found")
 	errInvalidSamplingPriorityHeader = errors.New("invalid Datadog sampling priority header found")
+	errIncompleteSpanContext         = errors.New("extracted an incomplete Datadog span context")
 )
 
 // Propagator serializes Span Context to/from Datadog headers.
 //
+// The zero value, Propagator{}, is ready to use: it speaks only the
+// x-datadog-* headers. Use NewPropagator with Options to opt into additional
+// behavior.
+//
 // Example Datadog format:
 // X-Datadog-Trace-Id: 16701352862047361693
 // X-Datadog-Parent-Id: 2939011537882399028
-type Propagator struct{}
+type Propagator struct {
+	withTraceState         bool
+	samplingPriorityMapper func(trace.SpanContext) int
+	originHeader           string
+	strictExtract          bool
+}
+
+// Option configures a Propagator constructed via NewPropagator.
+type Option func(*Propagator)
+
+// WithTraceState enables mirroring of Datadog-specific fields (sampling
+// priority, origin, the upper 64 bits of a 128-bit trace ID) into a "dd="
+// entry of the W3C TraceState, so that a propagator chained after this one
+// (e.g. propagation.TraceContext) can carry them forward without loss.
+func WithTraceState() Option {
+	return func(dd *Propagator) {
+		dd.withTraceState = true
+	}
+}
+
+// WithSamplingPriorityMapper overrides how a span's sampling decision is
+// translated into the x-datadog-sampling-priority header. fn receives the
+// outgoing span context and must return one of the Datadog sampling
+// priorities: USER_REJECT (-1), AUTO_REJECT (0), AUTO_KEEP (1), or
+// USER_KEEP (2). Without this option, Inject emits AUTO_KEEP/AUTO_REJECT
+// based solely on sc.IsSampled().
+func WithSamplingPriorityMapper(fn func(trace.SpanContext) int) Option {
+	return func(dd *Propagator) {
+		dd.samplingPriorityMapper = fn
+	}
+}
+
+// WithOriginHeader overrides the header name used for the Datadog origin
+// (default "x-datadog-origin"), used together with WithTraceState to carry
+// RUM/synthetics origin in and out of the "dd=" TraceState entry.
+func WithOriginHeader(header string) Option {
+	return func(dd *Propagator) {
+		dd.originHeader = header
+	}
+}
+
+// WithStrictExtract makes ExtractWithError return an error for malformed
+// Datadog headers instead of silently ignoring them. It has no effect on
+// Extract, which always falls back to returning the input context unchanged.
+func WithStrictExtract(strict bool) Option {
+	return func(dd *Propagator) {
+		dd.strictExtract = strict
+	}
+}
+
+// originHeaderName returns the configured origin header name, defaulting to
+// originHeaderKey.
+func (dd Propagator) originHeaderName() string {
+	if dd.originHeader != "" {
+		return dd.originHeader
+	}
+	return originHeaderKey
+}
+
+// NewPropagator returns a Propagator configured with the given Options.
+func NewPropagator(opts ...Option) *Propagator {
+	dd := &Propagator{}
+	for _, opt := range opts {
+		opt(dd)
+	}
+	return dd
+}
 
 // Asserts that the propagator implements the otel.TextMapPropagator interface at compile time.
 var _ propagation.TextMapPropagator = &Propagator{}
@@ -60,25 +147,167 @@ func (dd Propagator) Inject(ctx context.Context, carrier propagation.TextMapCarr
 	if sc.IsSampled() {
 		samplingFlag = isSampled
 	}
+	if dd.samplingPriorityMapper != nil {
+		samplingFlag = strconv.Itoa(dd.samplingPriorityMapper(sc))
+	}
 
 	carrier.Set(traceIDHeaderKey, convertOTtoDD(traceID))
 	carrier.Set(parentIDHeaderKey, convertOTtoDD(parentID))
 	carrier.Set(priorityHeaderKey, samplingFlag)
+
+	// The upper 64 bits of a 128-bit trace ID are carried out-of-band in
+	// x-datadog-tags so that pure 64-bit Datadog peers keep working off of
+	// x-datadog-trace-id alone.
+	if upper := traceID[:upperTraceIDLen]; upper != strings.Repeat("0", upperTraceIDLen) {
+		carrier.Set(tagsHeaderKey, upperTraceIDTagKey+"="+upper)
+	}
+
+	if dd.withTraceState {
+		if origin := ddTraceStateField(sc.TraceState(), "o"); origin != "" {
+			carrier.Set(dd.originHeaderName(), origin)
+		}
+		dd.injectTraceState(carrier, sc, samplingFlag)
+	}
+}
+
+// injectTraceState mirrors Datadog fields into a "dd=" TraceState entry so a
+// propagator chained after this one can emit them. An existing "dd=" entry
+// is preserved unchanged; one is only synthesized from the span context when
+// none is already present.
+func (dd Propagator) injectTraceState(carrier propagation.TextMapCarrier, sc trace.SpanContext, samplingFlag string) {
+	ts := sc.TraceState()
+	if ts.Get(ddTraceStateKey) == "" {
+		value := ddTraceStateValue(sc, samplingFlag)
+		if value == "" {
+			return
+		}
+		var err error
+		if ts, err = ts.Insert(ddTraceStateKey, value); err != nil {
+			return
+		}
+	}
+
+	carrier.Set(traceStateHeaderKey, ts.String())
+}
+
+// ddTraceStateValue derives a "dd=" TraceState value from a span context,
+// e.g. "s:1;p:53995c3f42cd8ad8;t.tid:4bf92f3577b34da6".
+func ddTraceStateValue(sc trace.SpanContext, samplingFlag string) string {
+	parts := []string{"s:" + samplingFlag}
+
+	if sc.SpanID().IsValid() {
+		parts = append(parts, "p:"+sc.SpanID().String())
+	}
+
+	if upper := sc.TraceID().String()[:upperTraceIDLen]; upper != strings.Repeat("0", upperTraceIDLen) {
+		parts = append(parts, "t.tid:"+upper)
+	}
+
+	return strings.Join(parts, ";")
 }
 
 // Extract gets a context from the carrier if it contains Datadog headers.
+// Malformed headers are ignored and the input context is returned unchanged;
+// use ExtractWithError if you need to observe that failure.
 func (dd Propagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	extracted, _ := dd.ExtractWithError(ctx, carrier)
+	return extracted
+}
+
+// ExtractWithError behaves like Extract, but when constructed with
+// WithStrictExtract(true) it returns an error instead of silently falling
+// back to ctx when the Datadog headers are missing or malformed.
+func (dd Propagator) ExtractWithError(ctx context.Context, carrier propagation.TextMapCarrier) (context.Context, error) {
 	var (
 		traceID = carrier.Get(traceIDHeaderKey)
 		spanID  = carrier.Get(parentIDHeaderKey)
 		sampled = carrier.Get(priorityHeaderKey)
 	)
 	sc, err := extract(traceID, spanID, sampled)
-	if err != nil || !sc.IsValid() {
-		return ctx
+	if err != nil {
+		if dd.strictExtract {
+			return ctx, err
+		}
+		return ctx, nil
+	}
+	if !sc.IsValid() {
+		if dd.strictExtract {
+			return ctx, errIncompleteSpanContext
+		}
+		return ctx, nil
+	}
+
+	if tid, ok := withUpperTraceID(sc.TraceID(), carrier.Get(tagsHeaderKey)); ok {
+		sc = sc.WithTraceID(tid)
+	}
+
+	if dd.withTraceState {
+		if ts, ok := ddTraceState(sampled, carrier.Get(dd.originHeaderName())); ok {
+			sc = sc.WithTraceState(ts)
+		}
+	}
+
+	return trace.ContextWithRemoteSpanContext(ctx, sc), nil
+}
+
+// ddTraceStateField reads a single "key:value" field out of the "dd="
+// TraceState entry, e.g. ddTraceStateField(ts, "o") for "dd=s:1;o:rum".
+func ddTraceStateField(ts trace.TraceState, key string) string {
+	for _, field := range strings.Split(ts.Get(ddTraceStateKey), ";") {
+		k, v, found := strings.Cut(field, ":")
+		if found && k == key {
+			return v
+		}
+	}
+	return ""
+}
+
+// ddTraceState builds a TraceState carrying a single "dd=" entry from the
+// sampling priority and origin found on the incoming Datadog headers.
+func ddTraceState(sampled, origin string) (trace.TraceState, bool) {
+	var parts []string
+	if sampled != "" {
+		parts = append(parts, "s:"+sampled)
+	}
+	if origin != "" {
+		parts = append(parts, "o:"+origin)
+	}
+	if len(parts) == 0 {
+		return trace.TraceState{}, false
+	}
+
+	ts, err := trace.TraceState{}.Insert(ddTraceStateKey, strings.Join(parts, ";"))
+	if err != nil {
+		return trace.TraceState{}, false
 	}
 
-	return trace.ContextWithRemoteSpanContext(ctx, sc)
+	return ts, true
+}
+
+// withUpperTraceID combines the lower 64 bits of id with the upper 64 bits
+// found in the _dd.p.tid entry of the x-datadog-tags header value, returning
+// the combined 128-bit trace ID. An absent, malformed, or wrong-length
+// _dd.p.tid falls back to 64-bit mode, leaving id untouched.
+func withUpperTraceID(id trace.TraceID, tags string) (trace.TraceID, bool) {
+	for _, tag := range strings.Split(tags, ",") {
+		key, value, found := strings.Cut(tag, "=")
+		if !found || key != upperTraceIDTagKey {
+			continue
+		}
+
+		if len(value) != upperTraceIDLen {
+			return id, false
+		}
+
+		full, err := trace.TraceIDFromHex(value + id.String()[upperTraceIDLen:])
+		if err != nil {
+			return id, false
+		}
+
+		return full, true
+	}
+
+	return id, false
 }
 
 func extract(traceID, spanID, sampled string) (trace.SpanContext, error) {
@@ -118,12 +347,14 @@ func extract(traceID, spanID, sampled string) (trace.SpanContext, error) {
 		}
 	}
 
-	sampledInt, err := strconv.Atoi(sampled)
-	if err != nil {
-		return empty, errInvalidSamplingPriorityHeader
-	}
-	if sampledInt >= 1 {
-		scc.TraceFlags = trace.FlagsSampled
+	if sampled != "" {
+		sampledInt, err := strconv.Atoi(sampled)
+		if err != nil {
+			return empty, errInvalidSamplingPriorityHeader
+		}
+		if sampledInt >= 1 {
+			scc.TraceFlags = trace.FlagsSampled
+		}
 	}
 
 	return trace.NewSpanContext(scc), nil
@@ -131,11 +362,18 @@ func extract(traceID, spanID, sampled string) (trace.SpanContext, error) {
 
 // Fields returns list of fields set with Inject.
 func (dd Propagator) Fields() []string {
-	return []string{
+	fields := []string{
 		traceIDHeaderKey,
 		parentIDHeaderKey,
 		priorityHeaderKey,
+		tagsHeaderKey,
 	}
+
+	if dd.withTraceState {
+		fields = append(fields, dd.originHeaderName(), traceStateHeaderKey)
+	}
+
+	return fields
 }
 
 // convert OpenTelemetry trace and span IDs to Datadog IDs
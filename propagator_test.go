@@ -1,8 +1,10 @@
 package datadog
 
 import (
+	"context"
 	"testing"
 
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,11 @@ var (
 	// 000000003ade68b1
 	spanIDSmall     = trace.SpanID{0, 0, 0, 0, 0x3a, 0xde, 0x68, 0xb1}
 	ddParentIDSmall = "987654321"
+
+	// 4bf92f3577b34da6a3ce929d0e0e4736
+	traceID128   = trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	ddTraceID128 = "11803532876627986230"
+	ddTagsHeader = "_dd.p.tid=4bf92f3577b34da6"
 )
 
 func TestExtractMultiple(t *testing.T) {
@@ -79,6 +86,18 @@ func TestExtractMultiple(t *testing.T) {
 			trace.SpanContextConfig{},
 			errInvalidSpanIDHeader,
 		},
+		{
+			// 32 characters, but not a valid decimal (x-datadog-trace-id is decimal, not hex).
+			"abcdefabcdefabcdefabcdefabcdefgh", ddParentID, "",
+			trace.SpanContextConfig{},
+			errMalformedTraceID,
+		},
+		{
+			// 20-digit decimal, one past math.MaxUint64 (18446744073709551615).
+			"18446744073709551616", ddParentID, "",
+			trace.SpanContextConfig{},
+			errMalformedTraceID,
+		},
 	}
 
 	for _, test := range tests {
@@ -99,3 +118,229 @@ func TestExtractMultiple(t *testing.T) {
 		assert.Equal(t, trace.NewSpanContext(test.expected), actual, info...)
 	}
 }
+
+func TestInject128BitTraceID(t *testing.T) {
+	dd := Propagator{}
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID128,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	carrier := propagation.MapCarrier{}
+	dd.Inject(ctx, carrier)
+
+	assert.Equal(t, ddTraceID128, carrier.Get(traceIDHeaderKey))
+	assert.Equal(t, ddTagsHeader, carrier.Get(tagsHeaderKey))
+}
+
+func TestInject64BitTraceIDOmitsTags(t *testing.T) {
+	dd := Propagator{}
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceIDSmall,
+		SpanID:  spanIDSmall,
+	}))
+
+	carrier := propagation.MapCarrier{}
+	dd.Inject(ctx, carrier)
+
+	assert.Empty(t, carrier.Get(tagsHeaderKey))
+}
+
+func TestExtract128BitTraceID(t *testing.T) {
+	dd := Propagator{}
+	carrier := propagation.MapCarrier{
+		traceIDHeaderKey:  ddTraceID128,
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+		tagsHeaderKey:     ddTagsHeader,
+	}
+
+	sc := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+	assert.Equal(t, traceID128, sc.TraceID())
+	assert.Equal(t, spanID, sc.SpanID())
+}
+
+func TestExtractMissingTagsFallsBackTo64Bit(t *testing.T) {
+	dd := Propagator{}
+	carrier := propagation.MapCarrier{
+		traceIDHeaderKey:  ddTraceID,
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+	}
+
+	sc := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+	assert.Equal(t, traceID, sc.TraceID())
+}
+
+func TestExtractMalformedUpperTraceIDFallsBackTo64Bit(t *testing.T) {
+	dd := Propagator{}
+	carrier := propagation.MapCarrier{
+		traceIDHeaderKey:  ddTraceID,
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+		tagsHeaderKey:     "_dd.p.tid=not-hex-and-too-long-to-be-valid",
+	}
+
+	sc := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+	assert.Equal(t, traceID, sc.TraceID())
+}
+
+func TestExtractWithTraceStatePopulatesDDEntry(t *testing.T) {
+	dd := NewPropagator(WithTraceState())
+	carrier := propagation.MapCarrier{
+		traceIDHeaderKey:  ddTraceID,
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+		originHeaderKey:   "rum",
+	}
+
+	sc := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+	assert.Equal(t, "s:1;o:rum", sc.TraceState().Get(ddTraceStateKey))
+}
+
+func TestExtractWithoutWithTraceStateLeavesTraceStateEmpty(t *testing.T) {
+	dd := Propagator{}
+	carrier := propagation.MapCarrier{
+		traceIDHeaderKey:  ddTraceID,
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+		originHeaderKey:   "rum",
+	}
+
+	sc := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+	assert.Empty(t, sc.TraceState().Get(ddTraceStateKey))
+}
+
+func TestInjectWithTraceStateSynthesizesDDEntry(t *testing.T) {
+	dd := NewPropagator(WithTraceState())
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	carrier := propagation.MapCarrier{}
+	dd.Inject(ctx, carrier)
+
+	assert.Equal(t, "dd=s:1;p:53995c3f42cd8ad8", carrier.Get(traceStateHeaderKey))
+}
+
+func TestInjectWithTraceStatePreservesExistingDDEntry(t *testing.T) {
+	dd := NewPropagator(WithTraceState())
+	existing, err := trace.TraceState{}.Insert(ddTraceStateKey, "s:2;o:synthetics")
+	assert.NoError(t, err)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		TraceState: existing,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), sc)
+
+	carrier := propagation.MapCarrier{}
+	dd.Inject(ctx, carrier)
+
+	assert.Equal(t, "dd=s:2;o:synthetics", carrier.Get(traceStateHeaderKey))
+}
+
+func TestInjectWithSamplingPriorityMapper(t *testing.T) {
+	dd := NewPropagator(WithSamplingPriorityMapper(func(sc trace.SpanContext) int {
+		return 2 // USER_KEEP
+	}))
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+
+	carrier := propagation.MapCarrier{}
+	dd.Inject(ctx, carrier)
+
+	assert.Equal(t, "2", carrier.Get(priorityHeaderKey))
+}
+
+func TestInjectWithOriginHeaderCustomName(t *testing.T) {
+	dd := NewPropagator(WithTraceState(), WithOriginHeader("x-custom-dd-origin"))
+	existing, err := trace.TraceState{}.Insert(ddTraceStateKey, "s:1;o:rum")
+	assert.NoError(t, err)
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+		TraceState: existing,
+	})
+	ctx := trace.ContextWithRemoteSpanContext(context.Background(), sc)
+
+	carrier := propagation.MapCarrier{}
+	dd.Inject(ctx, carrier)
+
+	assert.Equal(t, "rum", carrier.Get("x-custom-dd-origin"))
+	assert.Empty(t, carrier.Get(originHeaderKey))
+}
+
+func TestExtractWithErrorStrict(t *testing.T) {
+	dd := NewPropagator(WithStrictExtract(true))
+	carrier := propagation.MapCarrier{
+		traceIDHeaderKey:  "not-a-number",
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+	}
+
+	_, err := dd.ExtractWithError(context.Background(), carrier)
+	assert.Equal(t, errMalformedTraceID, err)
+}
+
+// FuzzExtract asserts that extract never panics on arbitrary input, always
+// returns either an error or a valid SpanContext, and that any SpanContext
+// it produces round-trips unchanged through Inject/Extract.
+func FuzzExtract(f *testing.F) {
+	f.Add(ddTraceID, ddParentID, isSampled)
+	f.Add(ddTraceIDSmall, ddParentIDSmall, notSampled)
+	f.Add(ddTraceID128, ddParentID, isSampled)
+	f.Add("", "", "")
+	f.Add("18446744073709551616", ddParentID, "1")
+	f.Add("abcdefabcdefabcdefabcdefabcdefgh", ddParentID, "1")
+	f.Add(ddTraceID, ddParentID, "not-a-number")
+
+	f.Fuzz(func(t *testing.T, traceID, spanID, sampled string) {
+		sc, err := extract(traceID, spanID, sampled)
+		if err != nil {
+			return
+		}
+		if !sc.IsValid() {
+			t.Fatalf("extract returned a nil error but an invalid span context: %+v", sc)
+		}
+
+		dd := Propagator{}
+		ctx := trace.ContextWithRemoteSpanContext(context.Background(), sc)
+		carrier := propagation.MapCarrier{}
+		dd.Inject(ctx, carrier)
+
+		got := trace.SpanContextFromContext(dd.Extract(context.Background(), carrier))
+		// dd.Extract always returns a remote span context, while sc (built
+		// directly by extract) is local, so compare the fields that matter
+		// for round-trip stability rather than the full SpanContext,
+		// including its remote flag.
+		if got.TraceID() != sc.TraceID() ||
+			got.SpanID() != sc.SpanID() ||
+			got.TraceFlags() != sc.TraceFlags() ||
+			got.TraceState().String() != sc.TraceState().String() {
+			t.Fatalf("round trip mismatch: got %+v, want %+v", got, sc)
+		}
+	})
+}
+
+func TestExtractWithErrorNonStrictSwallowsError(t *testing.T) {
+	dd := NewPropagator()
+	carrier := propagation.MapCarrier{
+		traceIDHeaderKey:  "not-a-number",
+		parentIDHeaderKey: ddParentID,
+		priorityHeaderKey: isSampled,
+	}
+
+	ctx, err := dd.ExtractWithError(context.Background(), carrier)
+	assert.NoError(t, err)
+	assert.Equal(t, context.Background(), ctx)
+}